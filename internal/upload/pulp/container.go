@@ -0,0 +1,166 @@
+package pulp
+
+import (
+	"fmt"
+
+	"github.com/osbuild/pulp-client/pulpclient"
+)
+
+// ContainerRepository is composer's own representation of a Pulp container
+// repository, keeping callers decoupled from the generated pulpclient types.
+type ContainerRepository struct {
+	Name string
+	Href string
+}
+
+// ContainerDistribution is composer's own representation of a Pulp container
+// distribution, i.e. a container repository made available under a base
+// path.
+type ContainerDistribution struct {
+	Name     string
+	BasePath string
+	Href     string
+}
+
+// ListContainerRepositories returns a map (repository name -> pulp href) of
+// existing container repositories.
+func (cl *Client) ListContainerRepositories() (map[string]string, error) {
+	list, resp, err := cl.client.RepositoriesContainerAPI.RepositoriesContainerContainerList(cl.ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("container repository list request returned an error: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	repos := make(map[string]string, list.GetCount())
+	for _, repo := range list.GetResults() {
+		repos[repo.Name] = repo.GetPulpHref()
+	}
+
+	return repos, nil
+}
+
+// buildContainerRepository builds the request body for
+// CreateContainerRepository. Split out so the name/description shaping can be
+// unit tested without a live Pulp instance.
+func buildContainerRepository(name, description string) pulpclient.ContainerContainerRepository {
+	repo := pulpclient.ContainerContainerRepository{
+		Name: name,
+	}
+	if description != "" {
+		repo.Description = *pulpclient.NewNullableString(&description)
+	}
+	return repo
+}
+
+// CreateContainerRepository creates a new container repository with a name
+// and description and returns it.
+func (cl *Client) CreateContainerRepository(name, description string) (*ContainerRepository, error) {
+	req := cl.client.RepositoriesContainerAPI.RepositoriesContainerContainerCreate(cl.ctx)
+	req = req.ContainerContainerRepository(buildContainerRepository(name, description))
+	result, resp, err := req.Execute()
+	if err != nil {
+		return nil, fmt.Errorf("container repository creation failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return &ContainerRepository{
+		Name: result.Name,
+		Href: result.GetPulpHref(),
+	}, nil
+}
+
+// ImportContainerImage imports a previously uploaded OCI image tarball
+// artifact (e.g. the href returned by Client.UploadFile or
+// Client.UploadFileChunked for a `skopeo copy ... oci-archive:` output) into
+// the container repository at repoHref, adding its manifest and blobs to the
+// repository. This task is asynchronous. The returned value is the href for
+// the import task; once it completes, look up the imported manifest's href
+// in its CreatedResources, pass it to ContainerManifestDigest, then call
+// PushContainerImage with the resulting digest to tag it.
+func (cl *Client) ImportContainerImage(artifactHref, repoHref string) (string, error) {
+	req := cl.client.RepositoriesContainerAPI.RepositoriesContainerContainerOciImport(cl.ctx, repoHref)
+	importOptions := pulpclient.OCIImport{
+		Artifact: artifactHref,
+	}
+
+	result, resp, err := req.OCIImport(importOptions).Execute()
+	if err != nil {
+		return "", fmt.Errorf("container OCI image import failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return result.Task, nil
+}
+
+// ContainerManifestDigest returns the content digest (e.g.
+// "sha256:...") of the manifest at manifestHref, one of the
+// CreatedResources of a completed ImportContainerImage task, for use with
+// PushContainerImage.
+func (cl *Client) ContainerManifestDigest(manifestHref string) (string, error) {
+	res, resp, err := cl.client.ContentManifestsAPI.ContentContainerManifestsRead(cl.ctx, manifestHref).Execute()
+	if err != nil {
+		return "", fmt.Errorf("error reading container manifest %q: %s (%s)", manifestHref, err.Error(), readBody(resp))
+	}
+
+	return res.Digest, nil
+}
+
+// buildTagImage builds the request body for PushContainerImage. Split out so
+// it's covered by a unit test pinning that Digest is populated from a content
+// digest, not a Pulp href - the bug fixed in this file's history.
+func buildTagImage(manifestDigest, tag string) pulpclient.TagImage {
+	return pulpclient.TagImage{
+		Digest: manifestDigest,
+		Tag:    tag,
+	}
+}
+
+// PushContainerImage tags the manifest identified by manifestDigest (its
+// content digest, e.g. "sha256:...", as returned by
+// ContainerManifestDigest - not a Pulp href) with tag in the container
+// repository at repoHref. The manifest must already be part of the
+// repository - imported with ImportContainerImage - this call does not
+// upload any content itself. This task is asynchronous. The returned value
+// is the href for the tag task.
+func (cl *Client) PushContainerImage(manifestDigest, tag, repoHref string) (string, error) {
+	req := cl.client.RepositoriesContainerAPI.RepositoriesContainerContainerTag(cl.ctx, repoHref)
+
+	result, resp, err := req.TagImage(buildTagImage(manifestDigest, tag)).Execute()
+	if err != nil {
+		return "", fmt.Errorf("container image push failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return result.Task, nil
+}
+
+// DistributeContainerRepo makes a container repository available for pull
+// under basePath. This task is asynchronous; once it completes, the
+// distribution can be looked up via ListContainerDistributions. The
+// returned value is the href for the distribute task.
+func (cl *Client) DistributeContainerRepo(basePath, name, repoHref string) (string, error) {
+	dist := *pulpclient.NewContainerContainerDistribution(basePath, name)
+	dist.SetRepository(repoHref)
+	res, resp, err := cl.client.DistributionsContainerAPI.DistributionsContainerContainerCreate(cl.ctx).ContainerContainerDistribution(dist).Execute()
+	if err != nil {
+		return "", fmt.Errorf("error distributing container repository: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return res.Task, nil
+}
+
+// ListContainerDistributions returns the container distributions currently
+// configured in Pulp.
+func (cl *Client) ListContainerDistributions() ([]ContainerDistribution, error) {
+	list, resp, err := cl.client.DistributionsContainerAPI.DistributionsContainerContainerList(cl.ctx).Execute()
+	if err != nil {
+		return nil, fmt.Errorf("container distribution list request returned an error: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	dists := make([]ContainerDistribution, 0, list.GetCount())
+	for _, dist := range list.GetResults() {
+		dists = append(dists, ContainerDistribution{
+			Name:     dist.Name,
+			BasePath: dist.BasePath,
+			Href:     dist.GetPulpHref(),
+		})
+	}
+
+	return dists, nil
+}
@@ -0,0 +1,29 @@
+package pulp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildContainerRepositoryWithoutDescription(t *testing.T) {
+	repo := buildContainerRepository("my-repo", "")
+	require.Equal(t, "my-repo", repo.Name)
+	require.False(t, repo.Description.IsSet())
+}
+
+func TestBuildContainerRepositoryWithDescription(t *testing.T) {
+	repo := buildContainerRepository("my-repo", "a description")
+	require.Equal(t, "my-repo", repo.Name)
+	require.True(t, repo.Description.IsSet())
+	require.Equal(t, "a description", *repo.Description.Get())
+}
+
+func TestBuildTagImageUsesDigestNotHref(t *testing.T) {
+	// Regression test for a bug where PushContainerImage passed a Pulp
+	// artifact/manifest href as TagImage.Digest instead of the manifest's
+	// actual content digest.
+	tagOptions := buildTagImage("sha256:abcdef", "latest")
+	require.Equal(t, "sha256:abcdef", tagOptions.Digest)
+	require.Equal(t, "latest", tagOptions.Tag)
+}
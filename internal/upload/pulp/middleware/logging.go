@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logging returns a Middleware that logs the method, path, status, and
+// duration of every request at the given level. A nil logger defaults to
+// logrus's standard logger.
+func Logging(logger *logrus.Logger, level logrus.Level) Middleware {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			fields := logrus.Fields{
+				"method":   req.Method,
+				"path":     req.URL.Path,
+				"duration": time.Since(start).String(),
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+			} else {
+				fields["status"] = resp.StatusCode
+			}
+			logger.WithFields(fields).Log(level, "pulp request")
+			return resp, err
+		})
+	}
+}
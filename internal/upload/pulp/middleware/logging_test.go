@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingRecordsMethodPathAndStatus(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+
+	rt := Logging(logger, logrus.InfoLevel)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/pulp/api/v3/repositories/", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+
+	out := buf.String()
+	require.Contains(t, out, `"method":"GET"`)
+	require.Contains(t, out, `"path":"/pulp/api/v3/repositories/"`)
+	require.Contains(t, out, `"status":200`)
+}
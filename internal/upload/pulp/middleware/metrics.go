@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsRecorder is implemented by anything that can record a completed
+// Pulp request. *Metrics below is the standard Prometheus-backed
+// implementation.
+type MetricsRecorder interface {
+	Observe(method, path, status string, duration time.Duration)
+}
+
+// Metrics is a MetricsRecorder backed by Prometheus, counting requests and
+// recording their latency, labeled by HTTP method and status.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// NewMetrics registers and returns a Metrics recorder with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pulp_client_requests_total",
+			Help: "Total number of requests made to Pulp, by method and status.",
+		}, []string{"method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pulp_client_request_duration_seconds",
+			Help:    "Latency of requests made to Pulp, by method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "status"}),
+	}
+	reg.MustRegister(m.requests, m.latency)
+	return m
+}
+
+func (m *Metrics) Observe(method, _, status string, duration time.Duration) {
+	m.requests.WithLabelValues(method, status).Inc()
+	m.latency.WithLabelValues(method, status).Observe(duration.Seconds())
+}
+
+// RecordMetrics returns a Middleware that reports request count and latency
+// to recorder, labeled by operation. A nil recorder is a no-op.
+func RecordMetrics(recorder MetricsRecorder) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if recorder == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+
+			status := "error"
+			if resp != nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			recorder.Observe(req.Method, req.URL.Path, status, time.Since(start))
+
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecorder struct {
+	method, status string
+	duration       time.Duration
+	calls          int
+}
+
+func (f *fakeRecorder) Observe(method, _, status string, duration time.Duration) {
+	f.method = method
+	f.status = status
+	f.duration = duration
+	f.calls++
+}
+
+func TestRecordMetricsObservesMethodAndStatus(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusCreated, nil), nil
+	})
+
+	recorder := &fakeRecorder{}
+	rt := RecordMetrics(recorder)(base)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 1, recorder.calls)
+	require.Equal(t, http.MethodPost, recorder.method)
+	require.Equal(t, "201", recorder.status)
+}
+
+func TestRecordMetricsNilRecorderIsNoop(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	rt := RecordMetrics(nil)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,33 @@
+// Package middleware implements a composable chain of http.RoundTripper
+// decorators for the pulp client, modeled on the smithy-go middleware stack
+// used by the AWS SDK (addRetry, addClientRequestID,
+// addRecordResponseTiming, addRequestResponseLogging, ...). Each concern
+// (request IDs, retries, rate limiting, logging, metrics, timeouts) lives in
+// its own file and is installed independently, in an explicit order, around
+// a base transport.
+package middleware
+
+import "net/http"
+
+// Middleware wraps a http.RoundTripper with additional behaviour and
+// returns the wrapped round tripper.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to an http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain installs mws around base, in the order given: the first middleware
+// in mws is outermost (sees the request first and the response last), the
+// last is innermost (closest to base). This mirrors the order operations are
+// listed in a smithy-go middleware stack build step.
+func Chain(base http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	rt := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		rt = mws[i](rt)
+	}
+	return rt
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimit returns a Middleware that blocks each outgoing request until
+// limiter permits it, protecting composer's shared Pulp instance from being
+// overwhelmed by many concurrent workers. A nil limiter is a no-op.
+func RateLimit(limiter *rate.Limiter) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if limiter == nil {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestRateLimitDelaysRequestsOverTheLimit(t *testing.T) {
+	attempts := 0
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	limiter := rate.NewLimiter(rate.Every(50*time.Millisecond), 1)
+	rt := RateLimit(limiter)(base)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	start := time.Now()
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	_, err = rt.RoundTrip(req)
+	require.NoError(t, err)
+	elapsed := time.Since(start)
+
+	require.Equal(t, 2, attempts)
+	require.GreaterOrEqual(t, elapsed, 40*time.Millisecond)
+}
+
+func TestRateLimitNilLimiterIsNoop(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	rt := RateLimit(nil)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRequestIDHeader is the header used to propagate a correlation ID
+// across a Pulp request, matching the header Pulp itself echoes back in its
+// responses.
+const DefaultRequestIDHeader = "Correlation-ID"
+
+// RequestID returns a Middleware that injects a random request ID into
+// header on every request that doesn't already carry one, so that the same
+// ID can be correlated across composer's logs and Pulp's own task/audit
+// logs. If the request already has the header set (e.g. propagated from an
+// upstream caller), it is left untouched.
+func RequestID(header string) Middleware {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(header) == "" {
+				req = req.Clone(req.Context())
+				req.Header.Set(header, uuid.NewString())
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDInjectsHeaderWhenAbsent(t *testing.T) {
+	var seen string
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		seen = req.Header.Get(DefaultRequestIDHeader)
+		return httptest.NewRecorder().Result(), nil
+	})
+
+	rt := RequestID(DefaultRequestIDHeader)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.NotEmpty(t, seen)
+	require.Empty(t, req.Header.Get(DefaultRequestIDHeader), "original request must not be mutated")
+}
+
+func TestRequestIDPreservesExistingHeader(t *testing.T) {
+	var seen string
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		seen = req.Header.Get(DefaultRequestIDHeader)
+		return httptest.NewRecorder().Result(), nil
+	})
+
+	rt := RequestID(DefaultRequestIDHeader)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	req.Header.Set(DefaultRequestIDHeader, "preset-id")
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, "preset-id", seen)
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// idempotentMethods is the set of HTTP verbs the retry middleware will
+// automatically retry. POST is never retried by this middleware, since
+// resubmitting it can create a resource or task twice; the generic
+// middleware sticks to verbs that are safe to retry unconditionally.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// retryable reports whether req is safe for this middleware to resend. A
+// request with a body can only be resent if req.GetBody is set: net/http
+// does not make that true by default for a plain io.Reader body (e.g. the
+// io.LimitReader over an *os.File that chunked uploads PUT), and resending
+// the original, already-drained reader would silently send a truncated body
+// under a stale Content-Range/Content-Length. Such requests are passed
+// through once without retry; UploadFileChunked, which streams chunks this
+// way, has its own per-chunk retry loop that reopens the file.
+func retryable(req *http.Request) bool {
+	if !idempotentMethods[req.Method] {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	return true
+}
+
+// RetryOptions configures the Retry middleware.
+type RetryOptions struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Defaults to 3.
+	MaxAttempts int
+	// BaseInterval is the delay before the first retry. Defaults to
+	// 200ms. Subsequent retries double this, up to MaxInterval.
+	BaseInterval time.Duration
+	// MaxInterval caps the backoff delay. Defaults to 5s.
+	MaxInterval time.Duration
+}
+
+func (o RetryOptions) withDefaults() RetryOptions {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 3
+	}
+	if o.BaseInterval <= 0 {
+		o.BaseInterval = 200 * time.Millisecond
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 5 * time.Second
+	}
+	return o
+}
+
+// Retry returns a Middleware that retries idempotent requests that fail
+// with a 5xx or 429 status, backing off exponentially and honoring a
+// Retry-After response header when present.
+func Retry(opts RetryOptions) Middleware {
+	opts = opts.withDefaults()
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !retryable(req) {
+				return next.RoundTrip(req)
+			}
+
+			var (
+				resp *http.Response
+				err  error
+			)
+			interval := opts.BaseInterval
+			for attempt := 1; attempt <= opts.MaxAttempts; attempt++ {
+				attemptReq := req
+				if attempt > 1 && req.GetBody != nil {
+					body, bodyErr := req.GetBody()
+					if bodyErr != nil {
+						return resp, bodyErr
+					}
+					attemptReq = req.Clone(req.Context())
+					attemptReq.Body = body
+				}
+
+				resp, err = next.RoundTrip(attemptReq)
+				if err == nil && !shouldRetry(resp) {
+					return resp, nil
+				}
+				if attempt == opts.MaxAttempts {
+					break
+				}
+
+				wait := jitter(interval)
+				if resp != nil {
+					if ra, ok := retryAfter(resp); ok {
+						wait = ra
+					}
+					io.Copy(io.Discard, resp.Body) //nolint:errcheck
+					resp.Body.Close()
+				}
+
+				time.Sleep(wait)
+				interval *= 2
+				if interval > opts.MaxInterval {
+					interval = opts.MaxInterval
+				}
+			}
+
+			return resp, err
+		})
+	}
+}
+
+func shouldRetry(resp *http.Response) bool {
+	if resp == nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter parses the Retry-After header as a duration in seconds, if
+// present.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// jitter returns d randomized by +/- 20%, to avoid synchronized retries
+// across concurrent workers hitting the same Pulp instance.
+func jitter(d time.Duration) time.Duration {
+	delta := float64(d) * 0.2
+	offset := (rand.Float64()*2 - 1) * delta
+	return time.Duration(float64(d) + offset)
+}
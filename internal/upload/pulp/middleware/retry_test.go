@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// nonRewindableBody is an io.ReadCloser that does not implement any of the
+// interfaces net/http recognizes as rewindable (bytes.Reader,
+// bytes.Buffer, strings.Reader), so http.NewRequest leaves req.GetBody nil -
+// mirroring the io.LimitReader over an *os.File used for chunked uploads.
+type nonRewindableBody struct {
+	io.Reader
+}
+
+func (nonRewindableBody) Close() error { return nil }
+
+func newResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+	}
+}
+
+func TestRetryRetriesServerErrorsOnIdempotentVerbs(t *testing.T) {
+	attempts := 0
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return newResponse(http.StatusServiceUnavailable, nil), nil
+		}
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	rt := Retry(RetryOptions{MaxAttempts: 3, BaseInterval: time.Millisecond, MaxInterval: time.Millisecond})(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 3, attempts)
+}
+
+func TestRetryDoesNotRetryNonIdempotentVerbs(t *testing.T) {
+	attempts := 0
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusServiceUnavailable, nil), nil
+	})
+
+	rt := Retry(RetryOptions{MaxAttempts: 3, BaseInterval: time.Millisecond})(base)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestRetryDoesNotRetryNonRewindableBody(t *testing.T) {
+	attempts := 0
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return newResponse(http.StatusServiceUnavailable, nil), nil
+	})
+
+	rt := Retry(RetryOptions{MaxAttempts: 3, BaseInterval: time.Millisecond})(base)
+	req := httptest.NewRequest(http.MethodPut, "http://example.com", nonRewindableBody{strings.NewReader("chunk")})
+	req.GetBody = nil // httptest.NewRequest may set GetBody for some body types; force the case under test
+
+	_, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts, "a PUT with a non-rewindable body must not be retried")
+}
+
+func TestRetryRebuildsBodyViaGetBodyOnRetry(t *testing.T) {
+	var seenBodies []string
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		b, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		seenBodies = append(seenBodies, string(b))
+		if len(seenBodies) < 2 {
+			return newResponse(http.StatusServiceUnavailable, nil), nil
+		}
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	rt := Retry(RetryOptions{MaxAttempts: 2, BaseInterval: time.Millisecond})(base)
+	req, err := http.NewRequest(http.MethodPut, "http://example.com", strings.NewReader("chunk-data"))
+	require.NoError(t, err)
+	require.NotNil(t, req.GetBody, "strings.Reader bodies get a GetBody from net/http")
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"chunk-data", "chunk-data"}, seenBodies, "every attempt must see the full, unconsumed body")
+}
+
+func TestRetryHonorsRetryAfterHeader(t *testing.T) {
+	attempts := 0
+	var slept time.Duration
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 2 {
+			header := http.Header{}
+			header.Set("Retry-After", "0")
+			return newResponse(http.StatusTooManyRequests, header), nil
+		}
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	start := time.Now()
+	rt := Retry(RetryOptions{MaxAttempts: 2, BaseInterval: time.Second})(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	slept = time.Since(start)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Less(t, slept, time.Second, "a zero Retry-After should short-circuit the exponential backoff")
+}
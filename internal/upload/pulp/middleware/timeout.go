@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout returns a Middleware that bounds each request to d by attaching a
+// context.WithTimeout to it. A zero d is a no-op.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		if d <= 0 {
+			return next
+		}
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			defer cancel()
+			return next.RoundTrip(req.WithContext(ctx))
+		})
+	}
+}
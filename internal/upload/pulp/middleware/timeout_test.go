@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutCancelsSlowRequests(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		select {
+		case <-time.After(time.Second):
+			return newResponse(http.StatusOK, nil), nil
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	})
+
+	rt := Timeout(10 * time.Millisecond)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	_, err := rt.RoundTrip(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTimeoutZeroIsNoop(t *testing.T) {
+	base := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, nil), nil
+	})
+
+	rt := Timeout(0)(base)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
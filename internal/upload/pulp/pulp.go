@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 
+	"github.com/osbuild/osbuild-composer/internal/upload/pulp/middleware"
 	"github.com/osbuild/pulp-client/pulpclient"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 type Client struct {
@@ -21,11 +26,100 @@ type Credentials struct {
 	Password string
 }
 
-func NewClient(url string, creds *Credentials) *Client {
-	ctx := context.WithValue(context.Background(), pulpclient.ContextServerIndex, 0)
-	transport := &http.Transport{}
+// clientOptions collects the knobs ClientOption functions may set before
+// NewClient assembles the middleware stack wrapped around every request.
+type clientOptions struct {
+	requestIDHeader string
+	retry           middleware.RetryOptions
+	disableRetry    bool
+	rateLimiter     *rate.Limiter
+	logger          *logrus.Logger
+	logLevel        logrus.Level
+	metrics         middleware.MetricsRecorder
+	timeout         time.Duration
+}
+
+// ClientOption configures optional cross-cutting behaviour (retries,
+// logging, metrics, ...) installed by NewClient around every Pulp request.
+type ClientOption func(*clientOptions)
+
+// WithRequestIDHeader overrides the header used to propagate a correlation
+// ID for each request. Defaults to middleware.DefaultRequestIDHeader.
+func WithRequestIDHeader(header string) ClientOption {
+	return func(o *clientOptions) { o.requestIDHeader = header }
+}
+
+// WithRetry overrides the retry behaviour for idempotent requests that fail
+// with a 5xx or 429 status. Pass WithDisableRetry to turn retries off
+// entirely.
+func WithRetry(opts middleware.RetryOptions) ClientOption {
+	return func(o *clientOptions) { o.retry = opts }
+}
+
+// WithDisableRetry disables the default retry middleware.
+func WithDisableRetry() ClientOption {
+	return func(o *clientOptions) { o.disableRetry = true }
+}
+
+// WithRateLimit bounds the rate of outgoing requests to limit per second
+// with the given burst.
+func WithRateLimit(limit rate.Limit, burst int) ClientOption {
+	return func(o *clientOptions) { o.rateLimiter = rate.NewLimiter(limit, burst) }
+}
+
+// WithLogger installs structured request logging at level using logger.
+func WithLogger(logger *logrus.Logger, level logrus.Level) ClientOption {
+	return func(o *clientOptions) {
+		o.logger = logger
+		o.logLevel = level
+	}
+}
+
+// WithMetrics installs recorder to observe request count and latency by
+// operation.
+func WithMetrics(recorder middleware.MetricsRecorder) ClientOption {
+	return func(o *clientOptions) { o.metrics = recorder }
+}
+
+// WithTimeout bounds every request to d.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(o *clientOptions) { o.timeout = d }
+}
+
+func NewClient(url string, creds *Credentials, opts ...ClientOption) *Client {
+	options := clientOptions{
+		requestIDHeader: middleware.DefaultRequestIDHeader,
+		logLevel:        logrus.InfoLevel,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	// Retry sits outside RateLimit so that every attempt Retry makes -
+	// not just the first - goes back through the limiter; otherwise a
+	// burst of retried requests after a 5xx/429 would bypass throttling
+	// entirely.
+	mws := []middleware.Middleware{middleware.RequestID(options.requestIDHeader)}
+	if !options.disableRetry {
+		mws = append(mws, middleware.Retry(options.retry))
+	}
+	if options.rateLimiter != nil {
+		mws = append(mws, middleware.RateLimit(options.rateLimiter))
+	}
+	if options.logger != nil {
+		mws = append(mws, middleware.Logging(options.logger, options.logLevel))
+	}
+	if options.metrics != nil {
+		mws = append(mws, middleware.RecordMetrics(options.metrics))
+	}
+	if options.timeout > 0 {
+		mws = append(mws, middleware.Timeout(options.timeout))
+	}
+
+	transport := middleware.Chain(&http.Transport{}, mws...)
 	httpClient := http.Client{Transport: transport}
 
+	ctx := context.WithValue(context.Background(), pulpclient.ContextServerIndex, 0)
 	pulpConfig := pulpclient.NewConfiguration()
 	pulpConfig.HTTPClient = &httpClient
 	pulpConfig.Servers = pulpclient.ServerConfigurations{pulpclient.ServerConfiguration{
@@ -178,4 +272,235 @@ func (cl *Client) TaskWaitingOrRunning(task string) bool {
 		return false
 	}
 	return state == TASK_RUNNING || state == TASK_WAITING
-}
\ No newline at end of file
+}
+
+// WaitOptions configures the polling behaviour of WaitForTask and WaitForTasks.
+type WaitOptions struct {
+	// BaseInterval is the initial delay between two polls of the task state.
+	BaseInterval time.Duration
+	// MaxInterval caps the delay between polls once the exponential backoff
+	// has grown past it.
+	MaxInterval time.Duration
+	// Jitter is the fraction (0-1) of the computed interval that is randomly
+	// added or subtracted to avoid synchronized polling across callers.
+	Jitter float64
+	// Deadline bounds the overall time spent waiting for the task, in
+	// addition to any deadline already set on the context passed to
+	// WaitForTask. A zero value means no additional deadline is applied.
+	Deadline time.Duration
+	// OnProgress, if set, is called every time a poll observes a Pulp
+	// progress report with a Done/Total counter. It may be called multiple
+	// times with the same values if the task hasn't progressed between
+	// polls.
+	OnProgress func(report ProgressReport)
+}
+
+// ProgressReport mirrors one entry of a Pulp task's progress_reports field.
+type ProgressReport struct {
+	Message string
+	Done    int64
+	Total   int64
+}
+
+// TaskResult is the outcome of waiting for a Pulp task to finish.
+type TaskResult struct {
+	Task             string
+	State            TaskState
+	Error            string
+	CreatedResources []string
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.BaseInterval <= 0 {
+		o.BaseInterval = time.Second
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = 30 * time.Second
+	}
+	if o.Jitter < 0 {
+		o.Jitter = 0
+	}
+	return o
+}
+
+// applyJitter returns interval adjusted by a random +/- jitter fraction.
+func applyJitter(interval time.Duration, jitter float64) time.Duration {
+	if jitter == 0 {
+		return interval
+	}
+	delta := float64(interval) * jitter
+	offset := (rand.Float64()*2 - 1) * delta
+	jittered := time.Duration(float64(interval) + offset)
+	if jittered < 0 {
+		return 0
+	}
+	return jittered
+}
+
+// terminal reports whether a task state is one Pulp will no longer transition
+// out of.
+func terminal(state TaskState) bool {
+	switch state {
+	case TASK_COMPLETED, TASK_FAILED, TASK_CANCELED:
+		return true
+	default:
+		return false
+	}
+}
+
+// readTaskResult reads the current state of a task and turns it into a
+// TaskResult, reporting progress via opts.OnProgress if set.
+func (cl *Client) readTaskResult(task string, opts WaitOptions) (TaskResult, *pulpclient.TaskResponse, error) {
+	res, resp, err := cl.client.TasksAPI.TasksRead(cl.ctx, task).Execute()
+	if err != nil {
+		return TaskResult{}, nil, fmt.Errorf("error reading task %s: %s (%s)", task, err.Error(), readBody(resp))
+	}
+
+	state := TaskState(res.GetState())
+	if state == "" {
+		return TaskResult{}, nil, fmt.Errorf("got empty task state for %s", task)
+	}
+
+	if opts.OnProgress != nil {
+		for _, pr := range res.GetProgressReports() {
+			opts.OnProgress(ProgressReport{
+				Message: pr.GetMessage(),
+				Done:    pr.GetDone(),
+				Total:   pr.GetTotal(),
+			})
+		}
+	}
+
+	result := TaskResult{
+		Task:             task,
+		State:            state,
+		Error:            res.GetError(),
+		CreatedResources: res.GetCreatedResources(),
+	}
+
+	return result, res, nil
+}
+
+// taskPoller abstracts the Pulp calls the WaitForTask state machine needs,
+// so that state machine (backoff, cancel-then-poll) can be exercised in
+// tests without a live Pulp server.
+type taskPoller interface {
+	readTask(task string, opts WaitOptions) (TaskResult, error)
+	cancelTask(task string) error
+}
+
+// clientTaskPoller is the taskPoller backed by a real Client.
+type clientTaskPoller struct {
+	cl *Client
+}
+
+func (p clientTaskPoller) readTask(task string, opts WaitOptions) (TaskResult, error) {
+	result, _, err := p.cl.readTaskResult(task, opts)
+	return result, err
+}
+
+func (p clientTaskPoller) cancelTask(task string) error {
+	_, _, err := p.cl.client.TasksAPI.TasksCancel(context.Background(), task).PatchedTaskCancel(pulpclient.PatchedTaskCancel{State: "canceled"}).Execute()
+	return err
+}
+
+// WaitForTask polls the state of task until it reaches a terminal state
+// (completed, failed, or canceled). The poll interval starts at
+// opts.BaseInterval and backs off exponentially up to opts.MaxInterval, with
+// +/- opts.Jitter randomization.
+//
+// If ctx is canceled or opts.Deadline elapses before the task reaches a
+// terminal state, WaitForTask requests a server-side cancel via
+// TasksAPI.TasksCancel and keeps polling (ignoring the now-canceled context)
+// until the task reaches a terminal state, so callers always get a
+// definitive result. That is normally TASK_CANCELED or TASK_FAILED, but a
+// task that finishes before the cancel takes effect is returned as
+// TASK_COMPLETED.
+func (cl *Client) WaitForTask(ctx context.Context, task string, opts WaitOptions) (TaskResult, error) {
+	return waitForTask(ctx, clientTaskPoller{cl: cl}, task, opts)
+}
+
+// waitForTask implements the WaitForTask state machine against an arbitrary
+// taskPoller.
+func waitForTask(ctx context.Context, poller taskPoller, task string, opts WaitOptions) (TaskResult, error) {
+	opts = opts.withDefaults()
+
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	interval := opts.BaseInterval
+	canceling := false
+	for {
+		result, err := poller.readTask(task, opts)
+		if err != nil {
+			return TaskResult{}, err
+		}
+
+		if terminal(result.State) {
+			return result, nil
+		}
+
+		if !canceling && ctx.Err() != nil {
+			canceling = true
+			if cancelErr := poller.cancelTask(task); cancelErr != nil {
+				logrus.Errorf("failed to request cancel of task %s: %s", task, cancelErr.Error())
+			}
+			// Switch to polling on a background context: we already
+			// honored the caller's cancellation by requesting the
+			// server-side cancel, but we still need to observe the
+			// task reach a terminal state before returning.
+			ctx = context.Background()
+			interval = opts.BaseInterval
+			continue
+		}
+
+		wait := applyJitter(interval, opts.Jitter)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			// only reachable while not yet canceling
+		}
+
+		interval *= 2
+		if interval > opts.MaxInterval {
+			interval = opts.MaxInterval
+		}
+	}
+}
+
+// WaitForTasks waits for many tasks concurrently, using at most concurrency
+// workers. Results are returned in the same order as tasks. If concurrency is
+// <= 0, a single worker is used.
+func (cl *Client) WaitForTasks(ctx context.Context, tasks []string, opts WaitOptions, concurrency int) ([]TaskResult, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]TaskResult, len(tasks))
+	errs := make([]error, len(tasks))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		i, task := i, task
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], errs[i] = cl.WaitForTask(ctx, task, opts)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
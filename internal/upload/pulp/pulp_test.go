@@ -0,0 +1,99 @@
+package pulp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminal(t *testing.T) {
+	cases := map[TaskState]bool{
+		TASK_WAITING:   false,
+		TASK_RUNNING:   false,
+		TASK_CANCELING: false,
+		TASK_SKIPPED:   false,
+		TASK_COMPLETED: true,
+		TASK_FAILED:    true,
+		TASK_CANCELED:  true,
+	}
+	for state, want := range cases {
+		require.Equal(t, want, terminal(state), "state %s", state)
+	}
+}
+
+func TestApplyJitterZeroIsNoop(t *testing.T) {
+	require.Equal(t, time.Second, applyJitter(time.Second, 0))
+}
+
+func TestApplyJitterStaysWithinBounds(t *testing.T) {
+	base := time.Second
+	for i := 0; i < 100; i++ {
+		got := applyJitter(base, 0.5)
+		require.GreaterOrEqual(t, got, time.Duration(0))
+		require.LessOrEqual(t, got, base+base/2)
+	}
+}
+
+func TestWaitOptionsWithDefaults(t *testing.T) {
+	opts := WaitOptions{}.withDefaults()
+	require.Equal(t, time.Second, opts.BaseInterval)
+	require.Equal(t, 30*time.Second, opts.MaxInterval)
+
+	custom := WaitOptions{BaseInterval: 5 * time.Second, MaxInterval: time.Minute}.withDefaults()
+	require.Equal(t, 5*time.Second, custom.BaseInterval)
+	require.Equal(t, time.Minute, custom.MaxInterval)
+}
+
+// fakeTaskPoller is an in-memory taskPoller for exercising the waitForTask
+// state machine without a live Pulp server.
+type fakeTaskPoller struct {
+	states    []TaskState
+	readCalls int32
+
+	canceled  int32
+	cancelErr error
+}
+
+func (p *fakeTaskPoller) readTask(task string, opts WaitOptions) (TaskResult, error) {
+	i := atomic.AddInt32(&p.readCalls, 1) - 1
+	idx := int(i)
+	if idx >= len(p.states) {
+		idx = len(p.states) - 1
+	}
+	return TaskResult{Task: task, State: p.states[idx]}, nil
+}
+
+func (p *fakeTaskPoller) cancelTask(task string) error {
+	atomic.AddInt32(&p.canceled, 1)
+	return p.cancelErr
+}
+
+func TestWaitForTaskReturnsOnTerminalState(t *testing.T) {
+	poller := &fakeTaskPoller{states: []TaskState{TASK_RUNNING, TASK_RUNNING, TASK_COMPLETED}}
+
+	result, err := waitForTask(context.Background(), poller, "task-href", WaitOptions{
+		BaseInterval: time.Millisecond,
+		MaxInterval:  time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TASK_COMPLETED, result.State)
+	require.EqualValues(t, 0, poller.canceled)
+}
+
+func TestWaitForTaskRequestsCancelOnContextCancelThenKeepsPolling(t *testing.T) {
+	poller := &fakeTaskPoller{states: []TaskState{TASK_RUNNING, TASK_RUNNING, TASK_CANCELED}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := waitForTask(ctx, poller, "task-href", WaitOptions{
+		BaseInterval: time.Millisecond,
+		MaxInterval:  time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TASK_CANCELED, result.State)
+	require.EqualValues(t, 1, poller.canceled, "cancel should be requested exactly once")
+}
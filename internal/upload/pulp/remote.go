@@ -0,0 +1,143 @@
+package pulp
+
+import (
+	"fmt"
+
+	"github.com/osbuild/pulp-client/pulpclient"
+)
+
+// SyncPolicy mirrors Pulp's download policy for a remote: how eagerly
+// content is fetched from upstream into the local Pulp instance.
+type SyncPolicy string
+
+const (
+	// SyncImmediate downloads all content immediately during sync.
+	SyncImmediate SyncPolicy = "immediate"
+	// SyncOnDemand downloads content only when it is first requested by a
+	// client, then caches it locally.
+	SyncOnDemand SyncPolicy = "on_demand"
+	// SyncStreamed streams content to clients without storing it locally.
+	SyncStreamed SyncPolicy = "streamed"
+)
+
+// RemoteOptions configures a Pulp remote pointing at upstream content.
+type RemoteOptions struct {
+	Policy SyncPolicy
+
+	Username string
+	Password string
+
+	// ClientCert and ClientKey are the PEM-encoded client certificate and
+	// key used for TLS client authentication against the upstream, if
+	// required.
+	ClientCert string
+	ClientKey  string
+	// CACert is the PEM-encoded CA bundle used to validate the upstream's
+	// TLS certificate.
+	CACert string
+
+	ProxyURL      string
+	ProxyUsername string
+	ProxyPassword string
+
+	// RateLimit caps the download rate from the upstream, in requests per
+	// second. Zero means unlimited.
+	RateLimit int
+	// TotalTimeout bounds, in seconds, how long a sync may take. Zero means
+	// no timeout.
+	TotalTimeout int
+}
+
+// buildOstreeRemote applies opts onto a new pulpclient.OstreeOstreeRemote
+// for name/url, leaving fields opts doesn't set at the Pulp API's own
+// defaults. Split out from CreateOSTreeRemote so the option-to-field mapping
+// can be unit tested without a live Pulp instance.
+func buildOstreeRemote(name, url string, opts RemoteOptions) pulpclient.OstreeOstreeRemote {
+	remote := *pulpclient.NewOstreeOstreeRemote(name, url)
+
+	if opts.Policy != "" {
+		remote.SetPolicy(string(opts.Policy))
+	}
+	if opts.Username != "" {
+		remote.SetUsername(opts.Username)
+	}
+	if opts.Password != "" {
+		remote.SetPassword(opts.Password)
+	}
+	if opts.ClientCert != "" {
+		remote.SetClientCert(opts.ClientCert)
+	}
+	if opts.ClientKey != "" {
+		remote.SetClientKey(opts.ClientKey)
+	}
+	if opts.CACert != "" {
+		remote.SetCaCert(opts.CACert)
+	}
+	if opts.ProxyURL != "" {
+		remote.SetProxyUrl(opts.ProxyURL)
+	}
+	if opts.ProxyUsername != "" {
+		remote.SetProxyUsername(opts.ProxyUsername)
+	}
+	if opts.ProxyPassword != "" {
+		remote.SetProxyPassword(opts.ProxyPassword)
+	}
+	if opts.RateLimit > 0 {
+		remote.SetRateLimit(int32(opts.RateLimit))
+	}
+	if opts.TotalTimeout > 0 {
+		remote.SetTotalTimeout(float32(opts.TotalTimeout))
+	}
+
+	return remote
+}
+
+// CreateOSTreeRemote creates a Pulp remote for an upstream ostree repository
+// at url and returns its href.
+func (cl *Client) CreateOSTreeRemote(name, url string, opts RemoteOptions) (string, error) {
+	remote := buildOstreeRemote(name, url, opts)
+
+	result, resp, err := cl.client.RemotesOstreeAPI.RemotesOstreeOstreeCreate(cl.ctx).OstreeOstreeRemote(remote).Execute()
+	if err != nil {
+		return "", fmt.Errorf("ostree remote creation failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return result.GetPulpHref(), nil
+}
+
+// SetRepositoryRemote binds remoteHref as the default remote of the
+// repository at repoHref, so that on-demand content in the repository is
+// fetched from it.
+func (cl *Client) SetRepositoryRemote(repoHref, remoteHref string) error {
+	update := pulpclient.PatchedostreeOstreeRepository{}
+	update.SetRemote(remoteHref)
+
+	_, resp, err := cl.client.RepositoriesOstreeAPI.RepositoriesOstreeOstreePartialUpdate(cl.ctx, repoHref).PatchedostreeOstreeRepository(update).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to set remote %q on repository %q: %s (%s)", remoteHref, repoHref, err.Error(), readBody(resp))
+	}
+
+	return nil
+}
+
+// SyncRepository starts a sync of repoHref against remoteHref. The download
+// policy (immediate/on_demand/streamed) is fixed on the remote itself (see
+// RemoteOptions.Policy on CreateOSTreeRemote) and is not re-selected here.
+// mirror controls whether the new repository version exactly mirrors the
+// remote's content (removing content that disappeared upstream) or is
+// additive, matching Pulp's own sync semantics. This task is asynchronous.
+// The returned value is the href for the sync task; callers typically
+// follow up with WaitForTask.
+func (cl *Client) SyncRepository(repoHref, remoteHref string, mirror bool) (string, error) {
+	syncOptions := pulpclient.RepositorySyncURL{
+		Remote: *pulpclient.NewNullableString(&remoteHref),
+		Mirror: *pulpclient.NewNullableBool(&mirror),
+	}
+
+	result, resp, err := cl.client.RepositoriesOstreeAPI.RepositoriesOstreeOstreeSync(cl.ctx, repoHref).RepositorySyncURL(syncOptions).Execute()
+	if err != nil {
+		return "", fmt.Errorf("ostree repository sync failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return result.Task, nil
+}
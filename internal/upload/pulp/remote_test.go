@@ -0,0 +1,45 @@
+package pulp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOstreeRemoteMinimal(t *testing.T) {
+	remote := buildOstreeRemote("my-remote", "https://example.com/repo", RemoteOptions{})
+	require.Equal(t, "my-remote", remote.Name)
+	require.Equal(t, "https://example.com/repo", remote.Url)
+	require.False(t, remote.HasPolicy())
+	require.False(t, remote.HasUsername())
+	require.False(t, remote.HasRateLimit())
+}
+
+func TestBuildOstreeRemoteAppliesAllOptions(t *testing.T) {
+	opts := RemoteOptions{
+		Policy:        SyncOnDemand,
+		Username:      "user",
+		Password:      "pass",
+		ClientCert:    "cert",
+		ClientKey:     "key",
+		CACert:        "ca",
+		ProxyURL:      "https://proxy.example.com",
+		ProxyUsername: "proxy-user",
+		ProxyPassword: "proxy-pass",
+		RateLimit:     100,
+		TotalTimeout:  30,
+	}
+	remote := buildOstreeRemote("my-remote", "https://example.com/repo", opts)
+
+	require.Equal(t, string(SyncOnDemand), remote.GetPolicy())
+	require.Equal(t, "user", remote.GetUsername())
+	require.Equal(t, "pass", remote.GetPassword())
+	require.Equal(t, "cert", remote.GetClientCert())
+	require.Equal(t, "key", remote.GetClientKey())
+	require.Equal(t, "ca", remote.GetCaCert())
+	require.Equal(t, "https://proxy.example.com", remote.GetProxyUrl())
+	require.Equal(t, "proxy-user", remote.GetProxyUsername())
+	require.Equal(t, "proxy-pass", remote.GetProxyPassword())
+	require.EqualValues(t, 100, remote.GetRateLimit())
+	require.EqualValues(t, 30, remote.GetTotalTimeout())
+}
@@ -0,0 +1,337 @@
+package pulp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/osbuild/pulp-client/pulpclient"
+)
+
+// defaultChunkSize is used by UploadFileChunked when UploadOptions.ChunkSize
+// is not set.
+const defaultChunkSize = 10 * 1024 * 1024 // 10 MiB
+
+// defaultChunkedThreshold is the file size below which UploadFileChunked
+// falls back to the single-shot UploadFile instead of chunking.
+const defaultChunkedThreshold = 100 * 1024 * 1024 // 100 MiB
+
+// defaultChunkRetryInterval is the initial backoff between retries of a
+// failed chunk PUT.
+const defaultChunkRetryInterval = 500 * time.Millisecond
+
+// UploadOptions controls the chunked upload behaviour of UploadFileChunked.
+type UploadOptions struct {
+	// ChunkSize is the size in bytes of each uploaded chunk. Defaults to
+	// 10 MiB.
+	ChunkSize int64
+	// Parallelism is the number of chunks PUT concurrently. Defaults to 1
+	// (sequential upload).
+	Parallelism int
+	// Threshold is the file size below which the single-shot UploadFile
+	// path is used instead of chunking. Defaults to 100 MiB.
+	Threshold int64
+	// ResumeFile, if set, is a path where upload progress is persisted so
+	// that an interrupted UploadFileChunked call can resume from the last
+	// committed chunk instead of starting over. If empty, no resume state
+	// is kept.
+	ResumeFile string
+}
+
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.Parallelism <= 0 {
+		o.Parallelism = 1
+	}
+	if o.Threshold <= 0 {
+		o.Threshold = defaultChunkedThreshold
+	}
+	return o
+}
+
+// resumeState is the on-disk representation of an in-progress chunked
+// upload, keyed by the source file path and size so a resume attempt against
+// a changed file is detected and discarded.
+type resumeState struct {
+	Path            string  `json:"path"`
+	Size            int64   `json:"size"`
+	ChunkSize       int64   `json:"chunk_size"`
+	UploadHref      string  `json:"upload_href"`
+	CommittedChunks []int64 `json:"committed_chunks"` // offsets of chunks already PUT successfully
+}
+
+func loadResumeState(resumeFile string, path string, size, chunkSize int64) *resumeState {
+	if resumeFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(resumeFile)
+	if err != nil {
+		return nil
+	}
+	var state resumeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	if state.Path != path || state.Size != size || state.ChunkSize != chunkSize {
+		return nil
+	}
+	return &state
+}
+
+func saveResumeState(resumeFile string, state *resumeState) error {
+	if resumeFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(resumeFile, data, 0o600)
+}
+
+func clearResumeState(resumeFile string) {
+	if resumeFile == "" {
+		return
+	}
+	_ = os.Remove(resumeFile)
+}
+
+// chunk is a [offset, offset+length) byte range of a file to be PUT as a
+// single upload chunk.
+type chunk struct {
+	offset int64
+	length int64
+}
+
+// pendingChunks splits a file of the given size into chunkSize chunks and
+// returns those whose offset is not already in committedOffsets, in
+// ascending order. The final chunk may be shorter than chunkSize.
+func pendingChunks(size, chunkSize int64, committedOffsets []int64) []chunk {
+	committed := make(map[int64]bool, len(committedOffsets))
+	for _, off := range committedOffsets {
+		committed[off] = true
+	}
+
+	var pending []chunk
+	for offset := int64(0); offset < size; offset += chunkSize {
+		if committed[offset] {
+			continue
+		}
+		length := chunkSize
+		if offset+length > size {
+			length = size - offset
+		}
+		pending = append(pending, chunk{offset: offset, length: length})
+	}
+
+	return pending
+}
+
+// UploadFileChunked uploads the file at path to Pulp's Uploads API in
+// configurable chunks, retrying individual chunks on any error, and
+// returns the href of the resulting artifact, exactly like UploadFile.
+//
+// Files smaller than opts.Threshold are uploaded with the existing
+// single-shot UploadFile instead. If opts.ResumeFile is set, progress is
+// persisted there so an interrupted call can resume from the last committed
+// chunk on a subsequent call with the same options.
+func (cl *Client) UploadFileChunked(path string, opts UploadOptions) (string, error) {
+	opts = opts.withDefaults()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	if size < opts.Threshold {
+		return cl.UploadFile(path)
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+
+	wholeFileSHA256, err := sha256File(fp)
+	if err != nil {
+		return "", fmt.Errorf("failed to checksum %q: %w", path, err)
+	}
+
+	state := loadResumeState(opts.ResumeFile, path, size, opts.ChunkSize)
+	if state == nil {
+		uploadHref, err := cl.createUpload(size)
+		if err != nil {
+			return "", err
+		}
+		state = &resumeState{
+			Path:       path,
+			Size:       size,
+			ChunkSize:  opts.ChunkSize,
+			UploadHref: uploadHref,
+		}
+	}
+
+	pending := pendingChunks(size, opts.ChunkSize, state.CommittedChunks)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, opts.Parallelism)
+	var wg sync.WaitGroup
+	for _, ch := range pending {
+		ch := ch
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := cl.uploadChunkWithRetry(path, state.UploadHref, ch.offset, ch.length, size); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				return
+			}
+
+			mu.Lock()
+			state.CommittedChunks = append(state.CommittedChunks, ch.offset)
+			_ = saveResumeState(opts.ResumeFile, state)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	href, err := cl.commitUpload(state.UploadHref, wholeFileSHA256)
+	if err != nil {
+		return "", err
+	}
+
+	clearResumeState(opts.ResumeFile)
+	return href, nil
+}
+
+func sha256File(fp *os.File) (string, error) {
+	if _, err := fp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, fp); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// sha256Chunk returns the hex-encoded SHA-256 of the [offset, offset+length)
+// region of the file at path.
+func sha256Chunk(path string, offset, length int64) (string, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer fp.Close()
+
+	if _, err := fp.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.LimitReader(fp, length)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// createUpload starts a new Pulp upload for a file of the given total size
+// and returns the upload's href.
+func (cl *Client) createUpload(size int64) (string, error) {
+	req := cl.client.UploadsAPI.UploadsCreate(cl.ctx)
+	res, resp, err := req.Upload(pulpclient.Upload{Size: size}).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return res.GetPulpHref(), nil
+}
+
+// uploadChunkWithRetry PUTs a single chunk of path at [offset, offset+length)
+// to the given upload, retrying with exponential backoff up to maxAttempts
+// times. Every error is retried: 4xx responses and local file or checksum
+// errors are not told apart from 5xx or network errors.
+func (cl *Client) uploadChunkWithRetry(path, uploadHref string, offset, length, total int64) error {
+	const maxAttempts = 5
+
+	var lastErr error
+	backoff := defaultChunkRetryInterval
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if err := cl.uploadChunk(path, uploadHref, offset, length, total); err != nil {
+			lastErr = err
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("failed to upload chunk at offset %d of %q after %d attempts: %w", offset, path, maxAttempts, lastErr)
+}
+
+func (cl *Client) uploadChunk(path, uploadHref string, offset, length, total int64) error {
+	chunkSHA256, err := sha256Chunk(path, offset, length)
+	if err != nil {
+		return fmt.Errorf("failed to checksum chunk at offset %d of %q: %w", offset, path, err)
+	}
+
+	fp, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	if _, err := fp.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	chunkData := io.LimitReader(fp, length)
+
+	contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, total)
+
+	req := cl.client.UploadsAPI.UploadsUpdate(cl.ctx, uploadHref).ContentRange(contentRange).Sha256(chunkSHA256).File(chunkData)
+	_, resp, err := req.Execute()
+	if err != nil {
+		return fmt.Errorf("chunk PUT failed: %s (%s)", err.Error(), readBody(resp))
+	}
+
+	return nil
+}
+
+// commitUpload finalizes an upload into an artifact once all chunks have
+// been PUT, verifying it against the whole-file SHA-256, and returns the
+// resulting artifact href.
+func (cl *Client) commitUpload(uploadHref, sha256sum string) (string, error) {
+	req := cl.client.UploadsAPI.UploadsCommit(cl.ctx, uploadHref)
+	res, resp, err := req.UploadCommit(pulpclient.UploadCommit{Sha256: sha256sum}).Execute()
+	if err != nil {
+		return "", fmt.Errorf("failed to commit upload %q: %s (%s)", uploadHref, err.Error(), readBody(resp))
+	}
+
+	return res.GetPulpHref(), nil
+}
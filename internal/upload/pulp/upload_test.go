@@ -0,0 +1,83 @@
+package pulp
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadOptionsWithDefaults(t *testing.T) {
+	opts := UploadOptions{}.withDefaults()
+	require.EqualValues(t, defaultChunkSize, opts.ChunkSize)
+	require.Equal(t, 1, opts.Parallelism)
+	require.EqualValues(t, defaultChunkedThreshold, opts.Threshold)
+
+	custom := UploadOptions{ChunkSize: 42, Parallelism: 4, Threshold: 7}.withDefaults()
+	require.EqualValues(t, 42, custom.ChunkSize)
+	require.Equal(t, 4, custom.Parallelism)
+	require.EqualValues(t, 7, custom.Threshold)
+}
+
+func TestPendingChunksSplitsWholeFile(t *testing.T) {
+	got := pendingChunks(25, 10, nil)
+	require.Equal(t, []chunk{
+		{offset: 0, length: 10},
+		{offset: 10, length: 10},
+		{offset: 20, length: 5},
+	}, got)
+}
+
+func TestPendingChunksSkipsCommittedOffsets(t *testing.T) {
+	got := pendingChunks(25, 10, []int64{10})
+	require.Equal(t, []chunk{
+		{offset: 0, length: 10},
+		{offset: 20, length: 5},
+	}, got)
+}
+
+func TestPendingChunksAllCommittedReturnsNone(t *testing.T) {
+	got := pendingChunks(20, 10, []int64{0, 10})
+	require.Empty(t, got)
+}
+
+func TestResumeStateRoundTrip(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	require.Nil(t, loadResumeState(resumeFile, "/path/to/file", 100, 10), "no state yet")
+
+	state := &resumeState{
+		Path:            "/path/to/file",
+		Size:            100,
+		ChunkSize:       10,
+		UploadHref:      "/pulp/api/v3/uploads/abc/",
+		CommittedChunks: []int64{0, 10},
+	}
+	require.NoError(t, saveResumeState(resumeFile, state))
+
+	loaded := loadResumeState(resumeFile, "/path/to/file", 100, 10)
+	require.NotNil(t, loaded)
+	require.Equal(t, state, loaded)
+
+	clearResumeState(resumeFile)
+	require.Nil(t, loadResumeState(resumeFile, "/path/to/file", 100, 10))
+}
+
+func TestResumeStateDiscardedWhenFileChanged(t *testing.T) {
+	resumeFile := filepath.Join(t.TempDir(), "resume.json")
+
+	state := &resumeState{Path: "/path/to/file", Size: 100, ChunkSize: 10, UploadHref: "href"}
+	require.NoError(t, saveResumeState(resumeFile, state))
+
+	// Same resume file, but the source file's size changed since: the
+	// persisted progress no longer applies and must be ignored.
+	require.Nil(t, loadResumeState(resumeFile, "/path/to/file", 200, 10))
+	require.Nil(t, loadResumeState(resumeFile, "/path/to/other-file", 100, 10))
+	require.Nil(t, loadResumeState(resumeFile, "/path/to/file", 100, 20))
+}
+
+func TestResumeStateEmptyPathIsNoop(t *testing.T) {
+	require.Nil(t, loadResumeState("", "/path/to/file", 100, 10))
+	require.NoError(t, saveResumeState("", &resumeState{}))
+	clearResumeState("") // must not panic
+}
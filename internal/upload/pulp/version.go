@@ -0,0 +1,116 @@
+package pulp
+
+import (
+	"fmt"
+)
+
+// ErrVersionConflict is returned by the *WithExpectedVersion methods when the
+// repository's current version no longer matches the version the caller
+// expected, so the caller's read-modify-write loop should re-read the
+// repository and retry.
+//
+// Note this is a best-effort, narrowing check, not a real compare-and-swap:
+// Pulp's ostree repository API has no conditional-write primitive (no
+// If-Match/base-version parameter accepted by the import or distribute
+// endpoints), so the version is checked and the task is submitted as two
+// separate requests. Two callers can both pass checkExpectedVersion against
+// the same observed version and then both submit - ErrVersionConflict only
+// shrinks the race window between the original unconditional ImportCommit
+// and DistributeOSTreeRepo, it does not close it. Closing it for real would
+// require a lock (e.g. composer-side, around a given repoHref) in addition
+// to this check.
+type ErrVersionConflict struct {
+	RepoHref string
+	Expected int64
+	Observed int64
+}
+
+func (e *ErrVersionConflict) Error() string {
+	return fmt.Sprintf("repository %q is at version %d, expected %d", e.RepoHref, e.Observed, e.Expected)
+}
+
+// RepositoryVersion returns the current version number of repoHref along
+// with the href of its latest repository version.
+func (cl *Client) RepositoryVersion(repoHref string) (int64, string, error) {
+	res, resp, err := cl.client.RepositoriesOstreeAPI.RepositoriesOstreeOstreeRead(cl.ctx, repoHref).Execute()
+	if err != nil {
+		return 0, "", fmt.Errorf("error reading repository %q: %s (%s)", repoHref, err.Error(), readBody(resp))
+	}
+
+	latest := res.GetLatestVersionHref()
+
+	version, err := versionFromHref(latest)
+	if err != nil {
+		return 0, "", fmt.Errorf("error parsing version from %q: %w", latest, err)
+	}
+
+	return version, latest, nil
+}
+
+// checkExpectedVersion returns ErrVersionConflict if the repository at
+// repoHref is not currently at expected. This only narrows the race between
+// the check and the caller's subsequent write; see the ErrVersionConflict
+// doc comment.
+func (cl *Client) checkExpectedVersion(repoHref string, expected int64) error {
+	observed, _, err := cl.RepositoryVersion(repoHref)
+	if err != nil {
+		return err
+	}
+	if observed != expected {
+		return &ErrVersionConflict{RepoHref: repoHref, Expected: expected, Observed: observed}
+	}
+	return nil
+}
+
+// ImportCommitWithExpectedVersion behaves like ImportCommit, but first
+// verifies that repoHref is still at version expected, returning
+// *ErrVersionConflict without submitting the import task if the repository
+// has moved on. The check and the import are two separate requests, not one
+// atomic operation - see the ErrVersionConflict doc comment for what this
+// does and does not protect against.
+func (cl *Client) ImportCommitWithExpectedVersion(commitHref, repoHref string, expected int64) (string, error) {
+	if err := cl.checkExpectedVersion(repoHref, expected); err != nil {
+		return "", err
+	}
+	return cl.ImportCommit(commitHref, repoHref)
+}
+
+// DistributeOSTreeRepoWithExpectedVersion behaves like DistributeOSTreeRepo,
+// but first verifies that repoHref is still at version expected, returning
+// *ErrVersionConflict without submitting the distribute task if the
+// repository has moved on. The check and the distribute call are two
+// separate requests, not one atomic operation - see the ErrVersionConflict
+// doc comment for what this does and does not protect against.
+func (cl *Client) DistributeOSTreeRepoWithExpectedVersion(basePath, name, repoHref string, expected int64) (string, error) {
+	if err := cl.checkExpectedVersion(repoHref, expected); err != nil {
+		return "", err
+	}
+	return cl.DistributeOSTreeRepo(basePath, name, repoHref)
+}
+
+// versionFromHref extracts the trailing version number from a Pulp
+// repository version href, e.g. ".../versions/3/" -> 3.
+func versionFromHref(href string) (int64, error) {
+	trimmed := href
+	for len(trimmed) > 0 && trimmed[len(trimmed)-1] == '/' {
+		trimmed = trimmed[:len(trimmed)-1]
+	}
+
+	idx := -1
+	for i := len(trimmed) - 1; i >= 0; i-- {
+		if trimmed[i] == '/' {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return 0, fmt.Errorf("no version segment found")
+	}
+
+	var version int64
+	if _, err := fmt.Sscanf(trimmed[idx+1:], "%d", &version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,40 @@
+package pulp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionFromHref(t *testing.T) {
+	version, err := versionFromHref("/pulp/api/v3/repositories/ostree/ostree/abc/versions/3/")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, version)
+}
+
+func TestVersionFromHrefWithoutTrailingSlash(t *testing.T) {
+	version, err := versionFromHref("/pulp/api/v3/repositories/ostree/ostree/abc/versions/3")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, version)
+}
+
+func TestVersionFromHrefZero(t *testing.T) {
+	version, err := versionFromHref("/pulp/api/v3/repositories/ostree/ostree/abc/versions/0/")
+	require.NoError(t, err)
+	require.EqualValues(t, 0, version)
+}
+
+func TestVersionFromHrefMalformed(t *testing.T) {
+	_, err := versionFromHref("/pulp/api/v3/repositories/ostree/ostree/abc/versions/not-a-number/")
+	require.Error(t, err)
+}
+
+func TestVersionFromHrefNoVersionSegment(t *testing.T) {
+	_, err := versionFromHref("3")
+	require.Error(t, err)
+}
+
+func TestErrVersionConflictError(t *testing.T) {
+	err := &ErrVersionConflict{RepoHref: "/pulp/api/v3/repositories/ostree/ostree/abc/", Expected: 3, Observed: 5}
+	require.Equal(t, `repository "/pulp/api/v3/repositories/ostree/ostree/abc/" is at version 5, expected 3`, err.Error())
+}